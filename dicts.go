@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// dictInfo describes a loaded dictionary for the /dicts endpoint.
+type dictInfo struct {
+	Name     string    `json:"name"`
+	Size     int       `json:"size"`
+	LoadedAt time.Time `json:"loadedAt"`
+}
+
+// dictionary pairs a loaded word list with the metadata describing it.
+type dictionary struct {
+	info  dictInfo
+	words *Words
+}
+
+// dictSet is the full collection of loaded dictionaries at a point in time.
+// A *dictSet is treated as immutable once built; reloading builds a new one
+// and swaps it in behind server.dicts (an atomic.Pointer), so requests in
+// flight keep using the dictSet they started with.
+//
+// Its mmapped indexes can't just be closed the moment a reload swaps it out,
+// though: a streamed /words response can still be reading from one for as
+// long as its client keeps the connection open, and munmapping memory out
+// from under an in-flight read segfaults the whole process, not just that
+// request. refs tracks how many holders are still using this generation
+// (one for server.dicts itself, plus one per in-flight request that called
+// acquire); closeNow only actually runs once the last holder releases it.
+type dictSet struct {
+	dicts       map[string]*dictionary
+	defaultName string
+	refs        int32
+}
+
+// get returns the named dictionary, or the default dictionary if name is
+// empty. ok is false if name doesn't name a loaded dictionary.
+func (ds *dictSet) get(name string) (*dictionary, bool) {
+	if name == "" {
+		name = ds.defaultName
+	}
+	d, ok := ds.dicts[name]
+	return d, ok
+}
+
+// acquire adds a holder to ds, reporting false (adding nothing) if ds has
+// already dropped to zero holders and closed. A caller that gets false back
+// must not use ds at all and should re-Load() server.dicts instead.
+func (ds *dictSet) acquire() bool {
+	for {
+		n := atomic.LoadInt32(&ds.refs)
+		if n <= 0 {
+			return false
+		}
+		if atomic.CompareAndSwapInt32(&ds.refs, n, n+1) {
+			return true
+		}
+	}
+}
+
+// release drops a holder added by acquire (or the implicit one a freshly
+// built dictSet starts with), closing ds's indexes once the last holder
+// drops off.
+func (ds *dictSet) release() {
+	if atomic.AddInt32(&ds.refs, -1) == 0 {
+		if err := ds.closeNow(); err != nil {
+			log.Print("warning: error closing previous dictionaries: ", err)
+		}
+	}
+}
+
+// closeNow unmaps the resources (mmapped indexes) held by every dictionary
+// in the set. Only release should call this, once it's established nothing
+// still holds a reference to ds.
+func (ds *dictSet) closeNow() error {
+	var firstErr error
+	for _, d := range ds.dicts {
+		if d.words.index == nil {
+			continue
+		}
+		if err := d.words.index.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// infos returns the info for every loaded dictionary, sorted by name.
+func (ds *dictSet) infos() []dictInfo {
+	names := make([]string, 0, len(ds.dicts))
+	for name := range ds.dicts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	out := make([]dictInfo, len(names))
+	for i, name := range names {
+		out[i] = ds.dicts[name].info
+	}
+	return out
+}
+
+// loadDictSet loads every dictionary for a server. If wordsDir is non-empty,
+// every regular file in it is loaded as a dictionary named for its base
+// filename (extension stripped), e.g. "TWL.txt" becomes dictionary "TWL".
+// Otherwise, the single file at wordsPath is loaded as the dictionary
+// "default". Either way, the first dictionary loaded (alphabetically, for a
+// directory) becomes the default used when a request omits dict=.
+func loadDictSet(wordsDir, wordsPath string) (*dictSet, error) {
+	if wordsDir == "" {
+		words, err := loadWords(wordsPath)
+		if err != nil {
+			return nil, err
+		}
+		const name = "default"
+		return &dictSet{
+			dicts: map[string]*dictionary{
+				name: {
+					info:  dictInfo{Name: name, Size: len(words.words), LoadedAt: time.Now()},
+					words: words,
+				},
+			},
+			defaultName: name,
+			refs:        1,
+		}, nil
+	}
+
+	entries, err := os.ReadDir(wordsDir)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		// Skip the on-disk signature indexes loadWords leaves next to each
+		// dictionary file (see index.go); they aren't word lists themselves.
+		if !e.IsDir() && filepath.Ext(e.Name()) != ".idx" {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+
+	ds := &dictSet{dicts: map[string]*dictionary{}, refs: 1}
+	for _, fname := range names {
+		name := strings.TrimSuffix(fname, filepath.Ext(fname))
+		words, err := loadWords(filepath.Join(wordsDir, fname))
+		if err != nil {
+			return nil, fmt.Errorf("loading dictionary %q: %w", name, err)
+		}
+		ds.dicts[name] = &dictionary{
+			info:  dictInfo{Name: name, Size: len(words.words), LoadedAt: time.Now()},
+			words: words,
+		}
+		if ds.defaultName == "" {
+			ds.defaultName = name
+		}
+	}
+	if len(ds.dicts) == 0 {
+		return nil, fmt.Errorf("no dictionaries found in %s", wordsDir)
+	}
+	return ds, nil
+}
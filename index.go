@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"syscall"
+)
+
+// The on-disk index maps a sorted-letters signature (see sortLetters) to the
+// list of dictionary words sharing it, so getWords can binary-search a fixed
+// number of signatures for a rack instead of scanning every word. The file
+// layout is:
+//
+//	indexMagic                     8 bytes
+//	sha256 of the words file      32 bytes
+//	number of entries               4 bytes, little-endian
+//	entries, sorted by key        indexEntrySize bytes each
+//	word blob                     newline-separated words, referenced by entries
+//
+// Rebuilding is keyed on the words file's content hash rather than its mtime
+// so a copy or checkout with a fresh mtime doesn't trigger a needless rebuild.
+const (
+	indexMagic = "WZIDX01\x00"
+	// indexKeyLen is the max signature length a fixed-size entry can hold.
+	// Signatures longer than this (exceedingly rare English words) are left
+	// out of the index entirely by buildWordIndex, which logs how many it
+	// drops. There's no scan fallback for them: a rack whose own signature
+	// (anagram mode) or a sub-signature (subset mode) exceeds indexKeyLen
+	// simply won't surface the excluded words. In practice a rack that long
+	// is already far beyond any real game's tile count.
+	indexKeyLen    = 32
+	indexEntrySize = indexKeyLen + 4 + 4 // key + blob offset + blob length
+)
+
+// wordIndex is a loaded (mmapped) index file.
+type wordIndex struct {
+	data    []byte // the whole mmapped file; kept so it can be munmapped
+	entries []byte // the sorted entry table, sliced from data
+	blob    []byte // the packed word blob, sliced from data
+	numKeys int
+}
+
+// buildWordIndex builds an index file at indexPath for words, embedding hash
+// so a later load can detect that the words file has since changed.
+func buildWordIndex(wordsPath, indexPath string, hash [32]byte, words *Words) error {
+	groups := map[string][]string{}
+	keys := make([]string, 0, len(words.words))
+	for _, w := range words.words {
+		if _, ok := groups[w.letters]; !ok {
+			keys = append(keys, w.letters)
+		}
+		groups[w.letters] = append(groups[w.letters], w.word)
+	}
+	sort.Strings(keys)
+
+	var blob bytes.Buffer
+	entries := make([]byte, 0, len(keys)*indexEntrySize)
+	skipped, skippedWords := 0, 0
+	for _, key := range keys {
+		if len(key) > indexKeyLen {
+			skipped++
+			skippedWords += len(groups[key])
+			continue
+		}
+		offset := blob.Len()
+		for _, word := range groups[key] {
+			blob.WriteString(word)
+			blob.WriteByte('\n')
+		}
+		var entry [indexEntrySize]byte
+		copy(entry[:indexKeyLen], key)
+		binary.LittleEndian.PutUint32(entry[indexKeyLen:], uint32(offset))
+		binary.LittleEndian.PutUint32(entry[indexKeyLen+4:], uint32(blob.Len()-offset))
+		entries = append(entries, entry[:]...)
+	}
+	if skipped > 0 {
+		log.Printf(
+			"word index: %d signature(s) longer than %d bytes excluded, dropping %d word(s) from index lookups entirely (no scan fallback covers them)",
+			skipped, indexKeyLen, skippedWords,
+		)
+	}
+
+	f, err := os.Create(indexPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.WriteString(indexMagic); err != nil {
+		return err
+	}
+	if _, err := f.Write(hash[:]); err != nil {
+		return err
+	}
+	var numEntries [4]byte
+	binary.LittleEndian.PutUint32(numEntries[:], uint32(len(entries)/indexEntrySize))
+	if _, err := f.Write(numEntries[:]); err != nil {
+		return err
+	}
+	if _, err := f.Write(entries); err != nil {
+		return err
+	}
+	_, err = f.Write(blob.Bytes())
+	return err
+}
+
+// loadWordIndex mmaps indexPath and validates it against hash, the content
+// hash of the words file it should have been built from. It returns an error
+// (and leaves nothing mapped) if the file is missing, malformed, or stale.
+func loadWordIndex(indexPath string, hash [32]byte) (*wordIndex, error) {
+	f, err := os.Open(indexPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	headerLen := len(indexMagic) + len(hash) + 4
+	if info.Size() < int64(headerLen) {
+		return nil, fmt.Errorf("index file too small")
+	}
+	data, err := syscall.Mmap(
+		int(f.Fd()), 0, int(info.Size()), syscall.PROT_READ, syscall.MAP_SHARED,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if string(data[:len(indexMagic)]) != indexMagic {
+		syscall.Munmap(data)
+		return nil, fmt.Errorf("bad index magic")
+	}
+	if !bytes.Equal(data[len(indexMagic):len(indexMagic)+len(hash)], hash[:]) {
+		syscall.Munmap(data)
+		return nil, fmt.Errorf("index is stale (words file changed)")
+	}
+	numKeys := int(binary.LittleEndian.Uint32(data[headerLen-4 : headerLen]))
+	entriesEnd := headerLen + numKeys*indexEntrySize
+	if len(data) < entriesEnd {
+		syscall.Munmap(data)
+		return nil, fmt.Errorf("index file truncated")
+	}
+	return &wordIndex{
+		data:    data,
+		entries: data[headerLen:entriesEnd],
+		blob:    data[entriesEnd:],
+		numKeys: numKeys,
+	}, nil
+}
+
+// Close unmaps the index's backing memory.
+func (idx *wordIndex) Close() error {
+	return syscall.Munmap(idx.data)
+}
+
+// lookup returns the words whose sorted-letters signature equals key, or nil
+// if no such signature is in the index.
+func (idx *wordIndex) lookup(key string) []string {
+	if len(key) > indexKeyLen {
+		return nil
+	}
+	var padded [indexKeyLen]byte
+	copy(padded[:], key)
+	i := sort.Search(idx.numKeys, func(i int) bool {
+		entryKey := idx.entries[i*indexEntrySize : i*indexEntrySize+indexKeyLen]
+		return bytes.Compare(entryKey, padded[:]) >= 0
+	})
+	if i >= idx.numKeys {
+		return nil
+	}
+	entry := idx.entries[i*indexEntrySize : (i+1)*indexEntrySize]
+	if !bytes.Equal(entry[:indexKeyLen], padded[:]) {
+		return nil
+	}
+	offset := binary.LittleEndian.Uint32(entry[indexKeyLen:])
+	length := binary.LittleEndian.Uint32(entry[indexKeyLen+4:])
+	region := bytes.TrimRight(idx.blob[offset:offset+length], "\n")
+	if len(region) == 0 {
+		return nil
+	}
+	parts := bytes.Split(region, []byte{'\n'})
+	words := make([]string, len(parts))
+	for i, p := range parts {
+		words[i] = string(p)
+	}
+	return words
+}
+
+// maxSubsetSignatures bounds how many sub-multiset signatures a subset-mode
+// query is allowed to enumerate. getWordsHandler rejects a request with 400
+// if subsetSignatureCount(letters) exceeds this before enumeration ever
+// starts; a rack with, say, all 26 letters doubled (3^26 ≈ 2.5×10^12
+// signatures) would otherwise run the server out of memory well before any
+// pruning or cancellation check got a chance to run. 1<<20 comfortably
+// covers any real rack (a handful of tiles) with headroom to spare.
+const maxSubsetSignatures = 1 << 20
+
+// subsetSignatureCount returns how many sub-multiset signatures
+// enumerateSubsetSignatures would generate for letters, i.e. the product of
+// (run length + 1) over each distinct letter present. It's computed
+// directly, without enumerating anything, so a pathological rack can be
+// rejected up front.
+func subsetSignatureCount(letters string) int {
+	count, run := 1, 0
+	for i := 0; i < len(letters); i++ {
+		if i > 0 && letters[i] != letters[i-1] {
+			count *= run + 1
+			run = 0
+			if count > maxSubsetSignatures {
+				return count
+			}
+		}
+		run++
+	}
+	if len(letters) > 0 {
+		count *= run + 1
+	}
+	return count
+}
+
+// enumerateSubsetSignatures calls yield once per sub-multiset signature of
+// letters, i.e. every way to take 0..count occurrences of each distinct
+// letter present (see subsetSignatureCount for how many that is), stopping
+// as soon as yield returns false. Signatures are generated lazily, one at a
+// time, rather than built up front into a slice, so a caller can prune
+// whole subtrees or react to cancellation (by returning false from yield)
+// while enumeration is still in progress instead of only after it's done.
+func enumerateSubsetSignatures(letters string, yield func(string) bool) bool {
+	type run struct {
+		b     byte
+		count int
+	}
+	var runs []run
+	for i := 0; i < len(letters); i++ {
+		if i > 0 && letters[i] == letters[i-1] {
+			runs[len(runs)-1].count++
+			continue
+		}
+		runs = append(runs, run{b: letters[i], count: 1})
+	}
+
+	buf := make([]byte, 0, len(letters))
+	var rec func(i int) bool
+	rec = func(i int) bool {
+		if i == len(runs) {
+			return yield(string(buf))
+		}
+		rn, base := runs[i], len(buf)
+		for n := 0; n <= rn.count; n++ {
+			buf = append(buf[:base], strings.Repeat(string(rn.b), n)...)
+			if !rec(i + 1) {
+				return false
+			}
+		}
+		return true
+	}
+	return rec(0)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestSubsetSignatureCount(t *testing.T) {
+	cases := []struct {
+		letters string
+		want    int
+	}{
+		{"", 1},
+		{"a", 2},
+		{"aa", 3},
+		{"ab", 4},
+		{"aab", 6},
+	}
+	for _, c := range cases {
+		if got := subsetSignatureCount(c.letters); got != c.want {
+			t.Errorf("subsetSignatureCount(%q) = %d, want %d", c.letters, got, c.want)
+		}
+	}
+}
+
+// TestSubsetSignatureCountCapsPathologicalRack reproduces the combinatorial
+// blowup a single "aabbcc...zz"-style rack caused before chunk0-3's fix:
+// subsetSignatureCount must report it (cheaply) as exceeding
+// maxSubsetSignatures so the caller can reject it before ever enumerating.
+func TestSubsetSignatureCountCapsPathologicalRack(t *testing.T) {
+	var letters string
+	for c := byte('a'); c <= 'z'; c++ {
+		letters += string(c) + string(c)
+	}
+	letters = sortLetters(letters)
+	if n := subsetSignatureCount(letters); n <= maxSubsetSignatures {
+		t.Fatalf("expected pathological rack to exceed the cap, got %d", n)
+	}
+}
+
+func TestEnumerateSubsetSignatures(t *testing.T) {
+	var got []string
+	enumerateSubsetSignatures(sortLetters("aab"), func(sig string) bool {
+		got = append(got, sig)
+		return true
+	})
+	want := []string{"", "a", "aa", "aab", "ab", "b"}
+	sort.Strings(got)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("enumerateSubsetSignatures(\"aab\") = %v, want %v", got, want)
+	}
+}
+
+func TestEnumerateSubsetSignaturesStopsWhenYieldReturnsFalse(t *testing.T) {
+	calls := 0
+	enumerateSubsetSignatures(sortLetters("aabbcc"), func(sig string) bool {
+		calls++
+		return calls < 3
+	})
+	if calls != 3 {
+		t.Fatalf("expected enumeration to stop right after yield returned false, got %d calls", calls)
+	}
+}
+
+func TestEnumerateSubsetSignaturesCountMatches(t *testing.T) {
+	letters := sortLetters("mississippi")
+	want := subsetSignatureCount(letters)
+	got := 0
+	enumerateSubsetSignatures(letters, func(string) bool {
+		got++
+		return true
+	})
+	if got != want {
+		t.Fatalf("enumerated %d signatures, subsetSignatureCount said %d", got, want)
+	}
+}
@@ -2,14 +2,22 @@ package main
 
 import (
 	"bufio"
+	"context"
+	"crypto/sha256"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
+	"os/signal"
+	"sort"
+	"strconv"
 	"strings"
+	"sync/atomic"
+	"syscall"
 
 	"github.com/hashicorp/golang-lru/v2"
 	"golang.org/x/exp/slices"
@@ -21,7 +29,19 @@ func main() {
 	addr := flag.String("addr", "127.0.0.1:8000", "Address to listen on")
 	wordsPath := flag.String(
 		"words", "",
-		"Path to file of sorted, valid words (valid meaning only contains ASCII letters)",
+		"Path to file of sorted, valid words (valid meaning only contains ASCII letters). "+
+			"Ignored if -words-dir is set.",
+	)
+	wordsDir := flag.String(
+		"words-dir", "",
+		"Path to a directory of dictionaries, one per file, named for the file "+
+			"(without extension). Selected per-request with dict=; the first "+
+			"(alphabetically) is used when dict= is omitted.",
+	)
+	reloadToken := flag.String(
+		"reload-token", "",
+		"Bearer token required by /admin/reload. If empty, /admin/reload is disabled "+
+			"(SIGHUP still works).",
 	)
 	logPath := flag.String("log", "", "Path to log file (empty is stderr)")
 	indexPath := flag.String(
@@ -30,7 +50,7 @@ func main() {
 	)
 	flag.Parse()
 
-	srvr, err := newServer(*addr, *wordsPath, *indexPath)
+	srvr, err := newServer(*addr, *wordsDir, *wordsPath, *indexPath, *reloadToken)
 	if err != nil {
 		log.Fatal("error creating server: ", err)
 	}
@@ -45,15 +65,21 @@ func main() {
 }
 
 type server struct {
-	words     *Words
-	srvr      *http.Server
-	indexPath string
+	// dicts holds the current *dictSet. Reloads (via SIGHUP or /admin/reload)
+	// build a new dictSet and swap it in here, so in-flight requests keep
+	// using the dictSet they started with rather than blocking on a lock.
+	dicts       atomic.Pointer[dictSet]
+	wordsDir    string
+	wordsPath   string
+	reloadToken string
+	srvr        *http.Server
+	indexPath   string
 	// Caches the sorted letters and JSON encoded sorted array of words combos.
 	cache *lru.TwoQueueCache[string, []byte]
 }
 
-func newServer(addr, wordsPath, indexPath string) (*server, error) {
-	words, err := loadWords(wordsPath)
+func newServer(addr, wordsDir, wordsPath, indexPath, reloadToken string) (*server, error) {
+	dicts, err := loadDictSet(wordsDir, wordsPath)
 	if err != nil {
 		return nil, err
 	}
@@ -61,74 +87,535 @@ func newServer(addr, wordsPath, indexPath string) (*server, error) {
 		return nil, err
 	}
 	cache, err := lru.New2Q[string, []byte](100)
-	return &server{
-		words: words,
+	if err != nil {
+		return nil, err
+	}
+	s := &server{
+		wordsDir:    wordsDir,
+		wordsPath:   wordsPath,
+		reloadToken: reloadToken,
 		srvr: &http.Server{
 			Addr: addr,
 		},
 		indexPath: indexPath,
 		cache:     cache,
-	}, err
+	}
+	s.dicts.Store(dicts)
+	return s, nil
+}
+
+// reload re-scans wordsDir (or re-reads wordsPath) and atomically swaps the
+// new dictSet in, invalidating the result cache since it's keyed against the
+// dictionary contents that produced each cached response. The dictSet it
+// replaces only has server.dicts' own reference dropped here: its indexes
+// stay mapped until every request still holding a reference (acquired via
+// acquireDicts, e.g. a long-lived /words?stream=... response) releases it
+// too, so a reload can never munmap memory an in-flight request is reading.
+func (s *server) reload() error {
+	dicts, err := loadDictSet(s.wordsDir, s.wordsPath)
+	if err != nil {
+		return err
+	}
+	old := s.dicts.Swap(dicts)
+	s.cache.Purge()
+	if old != nil {
+		old.release()
+	}
+	return nil
+}
+
+// acquireDicts returns the current dictSet with a reference held against it,
+// so a concurrent reload won't close its indexes out from under the caller;
+// the caller must call release() on the result exactly once when it's done
+// reading from it, even for the duration of a streamed response. It retries
+// if it raced a reload that dropped this exact generation to zero refs right
+// before the acquire (see dictSet.acquire); s.dicts.Load() will then return
+// the new generation, which always starts with a ref of its own.
+func (s *server) acquireDicts() *dictSet {
+	for {
+		ds := s.dicts.Load()
+		if ds.acquire() {
+			return ds
+		}
+	}
 }
 
 func (s *server) Run() error {
 	r := http.NewServeMux()
 	r.HandleFunc("/", s.homeHandler)
 	r.HandleFunc("/words", s.getWordsHandler)
+	r.HandleFunc("/dicts", s.dictsHandler)
+	r.HandleFunc("/admin/reload", s.adminReloadHandler)
 	s.srvr.Handler = r
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			if err := s.reload(); err != nil {
+				log.Print("error reloading dictionaries on SIGHUP: ", err)
+			} else {
+				log.Print("reloaded dictionaries on SIGHUP")
+			}
+		}
+	}()
+
 	log.Print("Running server on ", s.srvr.Addr)
 	return s.srvr.ListenAndServe()
 }
 
+// dictsHandler lists the currently loaded dictionaries.
+func (s *server) dictsHandler(w http.ResponseWriter, r *http.Request) {
+	infos := s.dicts.Load().infos()
+	infoJSON, err := json.Marshal(infos)
+	if err != nil {
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	w.Write(infoJSON)
+}
+
+// adminReloadHandler reloads the dictionaries on demand, gated by
+// s.reloadToken (sent as "Authorization: Bearer <token>"). It's disabled
+// (404) if no token was configured, since an unauthenticated reload endpoint
+// would let any caller trigger disk I/O and a cache purge.
+func (s *server) adminReloadHandler(w http.ResponseWriter, r *http.Request) {
+	if s.reloadToken == "" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Header.Get("Authorization") != "Bearer "+s.reloadToken {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if err := s.reload(); err != nil {
+		http.Error(w, "error reloading dictionaries: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func (s *server) homeHandler(w http.ResponseWriter, r *http.Request) {
 	http.ServeFile(w, r, s.indexPath)
 }
 
+// wordsQuery holds the parsed constraints for a /words request. It is also
+// used, via key(), to build the cache key so that two requests with the same
+// letters but different constraints don't collide in the cache.
+type wordsQuery struct {
+	// mode is either "subset" (default; any word makeable from a subset of the
+	// letters) or "anagram" (word must use every letter).
+	mode                           string
+	min, max                       int
+	contains, startsWith, endsWith string
+	// sortBy is "length", "score", "alpha", or "" (unsorted, default).
+	sortBy string
+	// order is "asc" (default) or "desc"; only meaningful if sortBy is set.
+	order string
+	// limit and offset paginate the sorted results; limit <= 0 means no limit.
+	limit, offset int
+	// scoring names the letter-value table used for WordMatch.Score (see
+	// scoring.go); defaults to defaultScoring.
+	scoring string
+}
+
+// key returns a string uniquely identifying the query for cache lookups.
+func (q wordsQuery) key(letters string) string {
+	return strings.Join([]string{
+		letters, q.mode,
+		strconv.Itoa(q.min), strconv.Itoa(q.max),
+		q.contains, q.startsWith, q.endsWith,
+		q.sortBy, q.order,
+		strconv.Itoa(q.limit), strconv.Itoa(q.offset),
+		q.scoring,
+	}, "\x00")
+}
+
+func parseWordsQuery(values url.Values) wordsQuery {
+	q := wordsQuery{
+		mode:       values.Get("mode"),
+		contains:   values.Get("contains"),
+		startsWith: values.Get("startsWith"),
+		endsWith:   values.Get("endsWith"),
+		sortBy:     values.Get("sort"),
+		order:      values.Get("order"),
+		scoring:    values.Get("scoring"),
+	}
+	if q.mode == "" {
+		q.mode = "subset"
+	}
+	if q.order == "" {
+		q.order = "asc"
+	}
+	if q.scoring == "" {
+		q.scoring = defaultScoring
+	}
+	if min, err := strconv.Atoi(values.Get("min")); err == nil {
+		q.min = min
+	}
+	if max, err := strconv.Atoi(values.Get("max")); err == nil {
+		q.max = max
+	}
+	if limit, err := strconv.Atoi(values.Get("limit")); err == nil {
+		q.limit = limit
+	}
+	if offset, err := strconv.Atoi(values.Get("offset")); err == nil {
+		q.offset = offset
+	}
+	return q
+}
+
 func (s *server) getWordsHandler(w http.ResponseWriter, r *http.Request) {
 	letters := sortLetters(r.URL.Query().Get("letters"))
 	if letters == "" {
 		http.Error(w, "invalid letters", http.StatusBadRequest)
 		return
 	}
-	wordsJSON, ok := s.cache.Get(letters)
+	ds := s.acquireDicts()
+	defer ds.release()
+	dictName := r.URL.Query().Get("dict")
+	dict, ok := ds.get(dictName)
+	if !ok {
+		http.Error(w, "unknown dictionary", http.StatusBadRequest)
+		return
+	}
+	query := parseWordsQuery(r.URL.Query())
+
+	// Subset-mode queries against an index enumerate every sub-multiset
+	// signature of letters (see enumerateSubsetSignatures); a rack with many
+	// distinct repeated letters can make that enumeration astronomically
+	// large, so reject it before any work starts rather than let the server
+	// try to build it. Anagram mode and the non-indexed/wildcard scan paths
+	// don't enumerate subsets, so they aren't at risk here.
+	if query.mode != "anagram" && dict.words.index != nil && !hasWildcard(letters) {
+		if n := subsetSignatureCount(letters); n > maxSubsetSignatures {
+			http.Error(w, "too many letters for a subset query", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if kind := streamModeFor(r); kind != streamNone {
+		s.streamWords(w, r, dict.words, letters, query, kind)
+		return
+	}
+
+	// Include the dictionary's name in the cache key since the same letters
+	// can match a different word set in a different dictionary.
+	cacheKey := dict.info.Name + "\x00" + query.key(letters)
+	wordsJSON, ok := s.cache.Get(cacheKey)
 	if ok {
 		w.Write(wordsJSON)
 		return
 	}
-	words := s.getWords(letters)
-	wordsJSON, err := json.Marshal(words)
+	words := []WordMatch{}
+	getWords(r.Context(), dict.words, letters, query, func(m WordMatch) bool {
+		words = append(words, m)
+		return true
+	})
+	if r.Context().Err() != nil {
+		// The client went away mid-scan; words is a truncated partial result,
+		// not a real answer, so don't write it back or let it poison the
+		// cache under the key a complete scan would use.
+		return
+	}
+	total := len(words)
+	sortWordMatches(words, query.sortBy, query.order)
+	words = paginate(words, query.offset, query.limit)
+	wordsJSON, err := json.Marshal(wordsResponse{Words: words, Total: total})
 	if err != nil {
 		http.Error(w, "internal server error", http.StatusInternalServerError)
 		return
 	}
 	w.Write(wordsJSON)
-	s.cache.Add(letters, wordsJSON)
+	s.cache.Add(cacheKey, wordsJSON)
 }
 
-// Expects the letters to be valid (see sortLetters).
-func (s *server) getWords(letters string) []string {
-	l, found, prev := len(letters), []string{}, byte(0)
-	wordsLen := len(s.words.words)
+// matchesConstraints reports whether word satisfies the length and string
+// constraints of q. It does not check canMakeFrom/anagram-length; that's
+// handled by the caller since it depends on the input letters.
+func (q wordsQuery) matchesConstraints(word Word) bool {
+	if q.min > 0 && word.Len() < q.min {
+		return false
+	}
+	if q.max > 0 && word.Len() > q.max {
+		return false
+	}
+	if q.contains != "" && !strings.Contains(word.word, q.contains) {
+		return false
+	}
+	if q.startsWith != "" && !strings.HasPrefix(word.word, q.startsWith) {
+		return false
+	}
+	if q.endsWith != "" && !strings.HasSuffix(word.word, q.endsWith) {
+		return false
+	}
+	return true
+}
+
+// streamKind identifies how (or whether) a /words response should be
+// streamed as matches are found, instead of being buffered and sent as one
+// JSON array.
+type streamKind int
+
+const (
+	streamNone streamKind = iota
+	streamNDJSON
+	streamSSE
+)
+
+// streamModeFor determines the stream kind for a request from the
+// stream= query param, falling back to the Accept header for clients
+// using EventSource (which can't set custom query params easily).
+func streamModeFor(r *http.Request) streamKind {
+	switch r.URL.Query().Get("stream") {
+	case "ndjson":
+		return streamNDJSON
+	case "sse":
+		return streamSSE
+	}
+	if strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		return streamSSE
+	}
+	return streamNone
+}
+
+// streamWords writes each match as getWords finds it rather than buffering
+// the whole result set, so the UI can render progressively for inputs that
+// produce thousands of matches. It bypasses s.cache, since there's nothing
+// sensible to cache a partial, flush-as-you-go response against, and stops
+// early if the client goes away (r.Context() is plumbed into getWords for
+// that).
+func (s *server) streamWords(
+	w http.ResponseWriter, r *http.Request, words *Words, letters string, query wordsQuery, kind streamKind,
+) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	if kind == streamSSE {
+		w.Header().Set("Content-Type", "text/event-stream")
+	} else {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	}
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	enc := json.NewEncoder(w)
+	getWords(r.Context(), words, letters, query, func(m WordMatch) bool {
+		if kind == streamSSE {
+			io.WriteString(w, "data: ")
+		}
+		if err := enc.Encode(m); err != nil {
+			return false
+		}
+		if kind == streamSSE {
+			io.WriteString(w, "\n")
+		}
+		flusher.Flush()
+		return r.Context().Err() == nil
+	})
+}
+
+// Blank names one wildcard tile's contribution to a WordMatch: the letter it
+// stood in for and that letter's index within WordMatch.Word. The index is
+// needed (a bare letter isn't enough) to tell apart which occurrence was
+// supplied by a wildcard when Word repeats a letter, e.g. "settee" with one
+// blank used for 'e' could be either the third or the sixth letter.
+type Blank struct {
+	Index  int    `json:"index"`
+	Letter string `json:"letter"`
+}
+
+// WordMatch is a single match returned by getWords. Blanks is only set when
+// letters contained wildcard tiles, and lists each wildcard used by word, in
+// the order they were consumed.
+type WordMatch struct {
+	Word   string  `json:"word"`
+	Score  int     `json:"score"`
+	Length int     `json:"length"`
+	Blanks []Blank `json:"blanks,omitempty"`
+}
+
+// wordsResponse is the JSON body of a non-streaming /words response.
+type wordsResponse struct {
+	Words []WordMatch `json:"words"`
+	// Total is the match count before offset/limit pagination was applied.
+	Total int `json:"total"`
+}
+
+// sortWordMatches sorts words in place by "length", "score", or "alpha"
+// (the word itself); any other value of by, including "", leaves words in
+// the order getWords found them. order is "desc" for descending, anything
+// else (including "") is ascending.
+func sortWordMatches(words []WordMatch, by, order string) {
+	var less func(a, b WordMatch) bool
+	switch by {
+	case "length":
+		less = func(a, b WordMatch) bool { return a.Length < b.Length }
+	case "score":
+		less = func(a, b WordMatch) bool { return a.Score < b.Score }
+	case "alpha":
+		less = func(a, b WordMatch) bool { return a.Word < b.Word }
+	default:
+		return
+	}
+	sort.Slice(words, func(i, j int) bool {
+		if order == "desc" {
+			return less(words[j], words[i])
+		}
+		return less(words[i], words[j])
+	})
+}
+
+// paginate returns the slice of words starting at offset (clamped into
+// range) and containing at most limit entries; limit <= 0 means no limit.
+func paginate(words []WordMatch, offset, limit int) []WordMatch {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(words) {
+		return []WordMatch{}
+	}
+	words = words[offset:]
+	if limit > 0 && limit < len(words) {
+		words = words[:limit]
+	}
+	return words
+}
+
+// hasWildcard reports whether letters contains at least one wildcard tile.
+func hasWildcard(letters string) bool {
+	return strings.IndexByte(letters, '?') >= 0 || strings.IndexByte(letters, '*') >= 0
+}
+
+// Expects the letters to be valid (see sortLetters). getWords invokes yield
+// once per match, in the same order it would previously have appeared in
+// the result slice, stopping early if yield returns false or ctx is done
+// (checked between candidates, not just once up front, so a long scan can
+// still be cancelled partway through).
+func getWords(ctx context.Context, words *Words, letters string, query wordsQuery, yield func(WordMatch) bool) {
+	// The signature index doesn't understand wildcards (they aren't part of
+	// any dictionary word's signature), so wildcard queries always fall back
+	// to the scan below.
+	if words.index != nil && !hasWildcard(letters) {
+		getWordsIndexed(ctx, words, letters, query, yield)
+		return
+	}
+
+	l := len(letters)
+	wordsLen := len(words.words)
+
+	// check reports whether scanning should continue.
+	check := func(word Word) bool {
+		if ctx.Err() != nil {
+			return false
+		}
+		if !query.matchesConstraints(word) {
+			return true
+		}
+		if query.mode == "anagram" {
+			// In anagram mode the word must use every one of the input letters
+			// (blanks included), so its length must match exactly; this also
+			// lets us skip the canMakeFrom scan entirely for candidates that
+			// can't match.
+			if word.Len() != l {
+				return true
+			}
+		}
+		if ok, blanks := word.canMakeFrom(letters); ok {
+			return yield(WordMatch{
+				Word:   word.word,
+				Score:  wordScore(word.word, query.scoring),
+				Length: word.Len(),
+				Blanks: blanks,
+			})
+		}
+		return true
+	}
+
+	if hasWildcard(letters) {
+		// A wildcard can stand in for a word's first letter too, so the
+		// per-first-letter bucket below can't be used to skip candidates.
+		for index := 0; index < wordsLen; index++ {
+			if !check(words.words[index]) {
+				return
+			}
+		}
+		return
+	}
+
+	prev := byte(0)
 	for i := 0; i < l; i++ {
 		b := letters[i]
 		// Skip letters that have already been done.
 		if prev == b {
 			continue
 		}
-		for index := s.words.letterIndexes[b-'a']; index < wordsLen; index++ {
-			word := s.words.words[index]
+		for index := words.letterIndexes[b-'a']; index < wordsLen; index++ {
+			word := words.words[index]
 			// If we have moved on to the next letter in the alphabet in the words
 			// list, go to the next letter in letters.
 			if word.word[0] != b {
 				break
 			}
-			if word.canMakeFrom(letters) {
-				found = append(found, word.word)
+			if !check(word) {
+				return
 			}
 		}
 		prev = b
 	}
-	return found
+}
+
+// getWordsIndexed answers a wildcard-free query using words.index instead of
+// scanning words.words. In anagram mode the input's own signature is looked
+// up directly; otherwise every sub-multiset signature of the input is looked
+// up one at a time via enumerateSubsetSignatures, which keeps this close to
+// the old behavior's complexity for a typical Scrabble rack while avoiding a
+// full dictionary scan. The caller is expected to have already rejected a
+// letters value whose subsetSignatureCount is unreasonably large (see
+// getWordsHandler); this still checks ctx between every candidate signature,
+// not just between lookup hits, so a cancelled request stops promptly
+// instead of finishing whatever's left of the enumeration.
+func getWordsIndexed(ctx context.Context, words *Words, letters string, query wordsQuery, yield func(WordMatch) bool) {
+	// add reports whether scanning should continue.
+	add := func(sig string) bool {
+		if query.min > 0 && len(sig) < query.min {
+			// Pruned: no word with this signature could pass the min filter.
+			return true
+		}
+		for _, w := range words.index.lookup(sig) {
+			if ctx.Err() != nil {
+				return false
+			}
+			word := Word{word: w}
+			if query.matchesConstraints(word) {
+				match := WordMatch{
+					Word:   w,
+					Score:  wordScore(w, query.scoring),
+					Length: word.Len(),
+				}
+				if !yield(match) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+
+	if query.mode == "anagram" {
+		add(letters)
+		return
+	}
+	enumerateSubsetSignatures(letters, func(sig string) bool {
+		if ctx.Err() != nil {
+			return false
+		}
+		if sig == "" {
+			return true
+		}
+		return add(sig)
+	})
 }
 
 type Word struct {
@@ -142,27 +629,93 @@ type Word struct {
 	word, letters string
 }
 
-// Expects valid input (see sortLetters).
-func (w Word) canMakeFrom(input string) bool {
+// Len returns the length of the word.
+func (w Word) Len() int {
+	return len(w.word)
+}
+
+// Expects valid input (see sortLetters). input may contain wildcard tiles
+// ('?' or '*'), each of which can stand in for any single missing letter.
+// Since sortLetters sorts ascending and wildcards are below 'a'/'A', they
+// always end up at the front of a sorted input, so every wildcard is seen
+// (and counted) before it's needed to fill a gap later in the scan.
+// Returns whether w can be made from input and, if any wildcards were used,
+// the Blanks they were used to supply, in the order they were consumed.
+func (w Word) canMakeFrom(input string) (bool, []Blank) {
 	ll, il := len(w.letters), len(input)
 	if ll > il {
-		return false
+		return false, nil
 	}
-	for li, ii := 0, 0; ii < il; ii++ {
-		lb, ib := w.letters[li], input[ii]
+	var blanks []Blank
+	// origIndexes maps a position in the sorted w.letters to the matching
+	// letter's position in w.word; built lazily, only once a blank is
+	// actually needed, since it's extra work most calls (no wildcards
+	// consumed) don't use.
+	var origIndexes []int
+	wildcards := 0
+	li := 0
+	for ii := 0; ii < il; ii++ {
+		ib := input[ii]
+		if ib == '?' || ib == '*' {
+			wildcards++
+			continue
+		}
+		lb := w.letters[li]
 		if lb == ib {
 			li++
 		} else if lb < ib {
-			// Return since the only case where the input letter is greater than the
-			// letters letter is when the latter isn't present in the input.
-			return false
+			// The only case where the input letter is greater than the letters
+			// letter is when the latter isn't present in the input; fall back to
+			// a wildcard if one is available instead of failing outright.
+			if wildcards == 0 {
+				return false, nil
+			}
+			wildcards--
+			if origIndexes == nil {
+				origIndexes = sortedLetterOrigIndexes(w.word, w.letters)
+			}
+			blanks = append(blanks, Blank{Index: origIndexes[li], Letter: string(lb)})
+			li++
+			ii-- // Reconsider this input letter against the next letters letter.
 		}
 		// End of the letters reached.
 		if li == ll {
-			return true
+			return true, blanks
 		}
 	}
-	return false
+	// Input ran out before letters did; see if leftover wildcards finish it.
+	for ; li < ll && wildcards > 0; li, wildcards = li+1, wildcards-1 {
+		if origIndexes == nil {
+			origIndexes = sortedLetterOrigIndexes(w.word, w.letters)
+		}
+		blanks = append(blanks, Blank{Index: origIndexes[li], Letter: string(w.letters[li])})
+	}
+	return li == ll, blanks
+}
+
+// sortedLetterOrigIndexes returns, for each position i in letters (the
+// sorted form of word), the position in word holding that same letter
+// occurrence. It lets blanks reported by canMakeFrom name a concrete index
+// in the original word instead of just a letter, which would be ambiguous
+// whenever word repeats a letter.
+func sortedLetterOrigIndexes(word, letters string) []int {
+	var counts [26]int
+	for i := 0; i < len(word); i++ {
+		counts[word[i]-'a']++
+	}
+	var next [26]int
+	total := 0
+	for c := 0; c < 26; c++ {
+		next[c] = total
+		total += counts[c]
+	}
+	indexes := make([]int, len(letters))
+	for i := 0; i < len(word); i++ {
+		c := word[i] - 'a'
+		indexes[next[c]] = i
+		next[c]++
+	}
+	return indexes
 }
 
 type Words struct {
@@ -173,6 +726,11 @@ type Words struct {
 	// Indexes of the the first words that start with a given letter.
 	// 'a' corresponds to 0 (in this array), 'b' to 1, etc.
 	letterIndexes [26]int
+	// index is the mmapped on-disk signature index (see index.go), used by
+	// getWords instead of letterIndexes when it's available. It's nil if the
+	// index couldn't be built or loaded, in which case getWords falls back to
+	// the letterIndexes scan.
+	index *wordIndex
 }
 
 // Expects the file to contain valid, sorted words
@@ -182,11 +740,25 @@ func loadWords(fpath string) (*Words, error) {
 		return nil, err
 	}
 	defer f.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, f); err != nil {
+		return nil, err
+	}
+	var hash [32]byte
+	copy(hash[:], hasher.Sum(nil))
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
 	words, r, prev := &Words{}, bufio.NewReader(f), byte(0)
 	for index := 0; true; index++ {
 		line, err := r.ReadString('\n')
 		if err != nil {
 			if err == io.EOF {
+				if ierr := attachIndex(words, fpath, hash); ierr != nil {
+					log.Print("warning: word index unavailable, falling back to scan: ", ierr)
+				}
 				return words, nil
 			}
 			return words, err
@@ -208,12 +780,32 @@ func loadWords(fpath string) (*Words, error) {
 	return words, nil
 }
 
-// Returns the sorted letters in lowercase. If there is a non-letter, an empty
-// string is returned. If the string is sorted, contains only letters, and is
-// all lowercase, the same string is returned without making any extra
-// allocations. Allocations will be made otherwise since the string needs to be
-// converted to and from a byte slice to make changes, whihc will make
-// allocations.
+// attachIndex loads the on-disk signature index for the words file at
+// wordsPath (building it first if it's missing or stale, see index.go) and
+// attaches it to words.index. Failures are non-fatal.
+func attachIndex(words *Words, wordsPath string, hash [32]byte) error {
+	indexPath := wordsPath + ".idx"
+	idx, err := loadWordIndex(indexPath, hash)
+	if err != nil {
+		if berr := buildWordIndex(wordsPath, indexPath, hash, words); berr != nil {
+			return berr
+		}
+		idx, err = loadWordIndex(indexPath, hash)
+		if err != nil {
+			return err
+		}
+	}
+	words.index = idx
+	return nil
+}
+
+// Returns the sorted letters in lowercase. Wildcard tiles ('?' or '*') are
+// allowed and passed through unchanged. If there is a character that's
+// neither a letter nor a wildcard, an empty string is returned. If the
+// string is sorted, contains only letters/wildcards, and is all lowercase,
+// the same string is returned without making any extra allocations.
+// Allocations will be made otherwise since the string needs to be converted
+// to and from a byte slice to make changes, whihc will make allocations.
 func sortLetters(letters string) string {
 	l := len(letters)
 	shouldSort, shouldLower := false, false
@@ -239,8 +831,8 @@ func sortLetters(letters string) string {
 				// Convert the variable to lowercase.
 				b += 'a' - 'A'
 				shouldLower = true
-			} else {
-				// Not a letter.
+			} else if b != '?' && b != '*' {
+				// Not a letter or a wildcard tile.
 				return ""
 			}
 		}
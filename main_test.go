@@ -0,0 +1,68 @@
+package main
+
+import "testing"
+
+func TestCanMakeFromNoWildcards(t *testing.T) {
+	cases := []struct {
+		word, input string
+		want        bool
+	}{
+		{"cab", "aabbcc", true},
+		{"cab", "abc", true},
+		{"cab", "ab", false},    // missing 'c'
+		{"cab", "aabc", true},   // extra letters in input are fine
+		{"zzz", "aabbcc", false},
+	}
+	for _, c := range cases {
+		w := Word{word: c.word, letters: sortLetters(c.word)}
+		ok, blanks := w.canMakeFrom(sortLetters(c.input))
+		if ok != c.want {
+			t.Errorf("canMakeFrom(%q, %q) = %v, want %v", c.word, c.input, ok, c.want)
+		}
+		if ok && len(blanks) != 0 {
+			t.Errorf("canMakeFrom(%q, %q) returned blanks %v with no wildcards in input", c.word, c.input, blanks)
+		}
+	}
+}
+
+func TestCanMakeFromWildcards(t *testing.T) {
+	w := Word{word: "cab", letters: sortLetters("cab")}
+	ok, blanks := w.canMakeFrom(sortLetters("ab?"))
+	if !ok {
+		t.Fatalf("expected cab to be makeable from ab?")
+	}
+	if len(blanks) != 1 {
+		t.Fatalf("expected 1 blank, got %v", blanks)
+	}
+	if blanks[0].Letter != "c" {
+		t.Fatalf("expected blank letter 'c', got %+v", blanks[0])
+	}
+	if w.word[blanks[0].Index] != 'c' {
+		t.Fatalf("blank index %d does not point at a 'c' in %q", blanks[0].Index, w.word)
+	}
+}
+
+// TestCanMakeFromBlanksDisambiguateDuplicateLetters ensures a blank used for
+// a repeated letter names a real, distinct occurrence in the word rather
+// than an ambiguous bare letter (the bug fixed for chunk0-2).
+func TestCanMakeFromBlanksDisambiguateDuplicateLetters(t *testing.T) {
+	w := Word{word: "settee", letters: sortLetters("settee")}
+	ok, blanks := w.canMakeFrom(sortLetters("sette?"))
+	if !ok {
+		t.Fatalf("expected settee to be makeable from sette?")
+	}
+	if len(blanks) != 1 {
+		t.Fatalf("expected 1 blank, got %v", blanks)
+	}
+	if got := w.word[blanks[0].Index]; got != 'e' {
+		t.Fatalf("blank index %d points at %q, not an 'e', in %q", blanks[0].Index, got, w.word)
+	}
+}
+
+func TestCanMakeFromWildcardsExhausted(t *testing.T) {
+	w := Word{word: "cabbage", letters: sortLetters("cabbage")}
+	ok, _ := w.canMakeFrom(sortLetters("ab?"))
+	if ok {
+		t.Fatalf("expected cabbage not to be makeable from ab? (not enough letters/wildcards)")
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import "testing"
+
+func TestSortWordMatches(t *testing.T) {
+	words := []WordMatch{
+		{Word: "cab", Score: 5, Length: 3},
+		{Word: "a", Score: 1, Length: 1},
+		{Word: "bat", Score: 3, Length: 3},
+	}
+
+	byAlphaAsc := append([]WordMatch(nil), words...)
+	sortWordMatches(byAlphaAsc, "alpha", "asc")
+	wantOrder(t, byAlphaAsc, "a", "bat", "cab")
+
+	byLengthDesc := append([]WordMatch(nil), words...)
+	sortWordMatches(byLengthDesc, "length", "desc")
+	wantOrder(t, byLengthDesc, "cab", "bat", "a")
+
+	byScoreAsc := append([]WordMatch(nil), words...)
+	sortWordMatches(byScoreAsc, "score", "asc")
+	wantOrder(t, byScoreAsc, "a", "bat", "cab")
+
+	// An unrecognized sortBy (including "") leaves the order untouched.
+	unsorted := append([]WordMatch(nil), words...)
+	sortWordMatches(unsorted, "", "asc")
+	wantOrder(t, unsorted, "cab", "a", "bat")
+}
+
+func wantOrder(t *testing.T, words []WordMatch, wantWords ...string) {
+	t.Helper()
+	if len(words) != len(wantWords) {
+		t.Fatalf("got %d words, want %d", len(words), len(wantWords))
+	}
+	for i, w := range wantWords {
+		if words[i].Word != w {
+			t.Fatalf("position %d: got %q, want %q (full: %v)", i, words[i].Word, w, words)
+		}
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	words := []WordMatch{{Word: "a"}, {Word: "b"}, {Word: "c"}, {Word: "d"}}
+
+	if got := paginate(words, 0, 0); len(got) != len(words) {
+		t.Fatalf("limit<=0 should mean no limit, got %v", got)
+	}
+	if got := paginate(words, 1, 2); len(got) != 2 || got[0].Word != "b" || got[1].Word != "c" {
+		t.Fatalf("offset=1,limit=2 got %v", got)
+	}
+	if got := paginate(words, 10, 2); len(got) != 0 {
+		t.Fatalf("offset past the end should return empty, got %v", got)
+	}
+	if got := paginate(words, -5, 2); len(got) != 2 || got[0].Word != "a" {
+		t.Fatalf("negative offset should clamp to 0, got %v", got)
+	}
+}
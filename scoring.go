@@ -0,0 +1,39 @@
+package main
+
+// Per-scheme letter value tables for scoring a word, indexed by lowercase
+// letter ('a' is index 0, 'z' is index 25).
+var (
+	scrabbleValues = [26]int{
+		1, 3, 3, 2, 1, 4, 2, 4, 1, 8, 5, 1, 3,
+		1, 1, 3, 10, 1, 1, 1, 1, 4, 4, 8, 4, 10,
+	}
+	wwfValues = [26]int{
+		1, 4, 4, 2, 1, 4, 3, 3, 1, 10, 5, 2, 4,
+		2, 1, 4, 10, 1, 1, 1, 2, 5, 4, 8, 3, 10,
+	}
+)
+
+// defaultScoring is used for a request that doesn't specify scoring=.
+const defaultScoring = "scrabble"
+
+// wordScore returns word's point value under the named scheme: "scrabble",
+// "wwf", or "simple" (one point per letter, i.e. just the word's length).
+// An unrecognized scheme scores like "simple".
+func wordScore(word, scheme string) int {
+	var table *[26]int
+	switch scheme {
+	case "scrabble":
+		table = &scrabbleValues
+	case "wwf":
+		table = &wwfValues
+	default:
+		return len(word)
+	}
+	score := 0
+	for i := 0; i < len(word); i++ {
+		if b := word[i]; b >= 'a' && b <= 'z' {
+			score += table[b-'a']
+		}
+	}
+	return score
+}
@@ -0,0 +1,20 @@
+package main
+
+import "testing"
+
+func TestWordScore(t *testing.T) {
+	cases := []struct {
+		word, scheme string
+		want         int
+	}{
+		{"cab", "scrabble", 3 + 1 + 3},
+		{"cab", "wwf", 4 + 1 + 4},
+		{"cab", "simple", 3},
+		{"cab", "unknown-scheme", 3},
+	}
+	for _, c := range cases {
+		if got := wordScore(c.word, c.scheme); got != c.want {
+			t.Errorf("wordScore(%q, %q) = %d, want %d", c.word, c.scheme, got, c.want)
+		}
+	}
+}